@@ -0,0 +1,135 @@
+// Package amdkds bootstraps the AMD SEV-SNP ASK/ARK signing chain from AMD's Key Distribution
+// Service, rather than requiring operators to place the ASK/ARK certificates on disk before the daemon
+// can start.
+package amdkds
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/google/go-sev-guest/verify/trust"
+
+	"github.com/industrial-data-space/idscp2-rat-drivers/idscp2-ra-snp/snp-attestd/product"
+)
+
+// TrustedARKFingerprints holds the SHA-256 fingerprint of AMD's published SEV-SNP ARK public
+// key for each product line. This is the compiled-in trust anchor: a freshly fetched ARK is
+// only trusted if it matches an entry here, so a compromised or spoofed kdsintf.amd.com can't
+// hand us an arbitrary root of trust.
+//
+// This map ships empty on purpose. We have no channel in this repo for shipping a value we
+// can independently stand behind as "this is what AMD actually publishes", and a wrong digest
+// here is worse than no digest at all — it would make AutoFetchRootChain trust an attacker's
+// ARK while looking like it's doing the opposite. Before enabling AutoFetchRootChain, populate
+// this map (e.g. from your deployment's init code) with digests you have verified yourselves,
+// such as `sha256sum` of the SubjectPublicKeyInfo of the ARK certificate served at
+// https://kdsintf.amd.com/vcek/v1/{Milan,Genoa,Turin}/cert_chain, fetched and checked against
+// AMD's own published documentation over a channel you trust.
+var TrustedARKFingerprints = map[string]string{}
+
+// FetchRootChain fetches the ASK+ARK certificate chain for p from
+// https://kdsintf.amd.com/vcek/v1/{product}/cert_chain, using getter to perform the request,
+// and verifies the fetched ARK against TrustedARKFingerprints before returning. Both certificates are
+// returned DER-encoded.
+func FetchRootChain(p product.Product, getter trust.HTTPSGetter) (askDER, arkDER []byte, err error) {
+	// AMD's KDS endpoint is case-sensitive and expects the canonical product name (e.g.
+	// "Milan"), unlike CacheFileName's lower-cased form which is only a local file name.
+	url := fmt.Sprintf("https://kdsintf.amd.com/vcek/v1/%s/cert_chain", p.Name)
+
+	data, err := getter.Get(url)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not fetch AMD's cert chain for %s: %w", p.Name, err)
+	}
+
+	ask, ark, err := splitChain(data)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not parse AMD's cert chain for %s: %w", p.Name, err)
+	}
+
+	if err := verifyARK(ark, p); err != nil {
+		return nil, nil, err
+	}
+
+	return ask.Raw, ark.Raw, nil
+}
+
+// Bootstrap fetches and verifies p's root chain via FetchRootChain and writes it into dir using
+// p's cache file names, so subsequent runs can load it offline.
+func Bootstrap(dir string, p product.Product, getter trust.HTTPSGetter) error {
+	ask, ark, err := FetchRootChain(p, getter)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("could not create cache directory: %w", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, p.CacheFileName("ask")), ask, 0644); err != nil {
+		return fmt.Errorf("could not write the ASK certificate: %w", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, p.CacheFileName("ark")), ark, 0644); err != nil {
+		return fmt.Errorf("could not write the ARK certificate: %w", err)
+	}
+
+	return nil
+}
+
+// splitChain parses the two PEM-encoded certificates AMD's cert_chain endpoint returns and
+// tells the self-signed ARK apart from the ASK it signs.
+func splitChain(pemData []byte) (ask, ark *x509.Certificate, err error) {
+	rest := pemData
+	var certs []*x509.Certificate
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, nil, fmt.Errorf("could not parse certificate: %w", err)
+		}
+		certs = append(certs, cert)
+	}
+
+	if len(certs) != 2 {
+		return nil, nil, fmt.Errorf("expected exactly 2 certificates in AMD's cert chain, got %d", len(certs))
+	}
+
+	for _, cert := range certs {
+		if bytes.Equal(cert.RawIssuer, cert.RawSubject) {
+			ark = cert
+		} else {
+			ask = cert
+		}
+	}
+
+	if ask == nil || ark == nil {
+		return nil, nil, fmt.Errorf("could not tell the ASK and ARK apart in AMD's cert chain")
+	}
+
+	return ask, ark, nil
+}
+
+func verifyARK(ark *x509.Certificate, p product.Product) error {
+	want, ok := TrustedARKFingerprints[p.Name]
+	if !ok {
+		return fmt.Errorf("no trusted ARK fingerprint configured for product %q; populate amdkds.TrustedARKFingerprints before enabling AutoFetchRootChain", p.Name)
+	}
+
+	got := sha256.Sum256(ark.RawSubjectPublicKeyInfo)
+	if hex.EncodeToString(got[:]) != want {
+		return fmt.Errorf("fetched ARK for %q does not match the embedded AMD root of trust", p.Name)
+	}
+
+	return nil
+}
@@ -0,0 +1,38 @@
+// Package certcache caches the VCEK/ASK/ARK certificates used to verify SEV-SNP attestation
+// reports. AMD's Key Distribution Service rate-limits aggressively, so every backend here fronts
+// a Fetcher with a TTL, and concurrent misses for the same certificate are coalesced into a
+// single upstream request.
+package certcache
+
+import (
+	"time"
+
+	"github.com/google/go-sev-guest/kds"
+
+	"github.com/industrial-data-space/idscp2-rat-drivers/idscp2-ra-snp/snp-attestd/product"
+)
+
+// DefaultTTL is used by backends that do not have an explicit TTL configured.
+const DefaultTTL = 6 * time.Hour
+
+// Cache retrieves and stores the certificates needed to verify a VCEK's signature chain. Every
+// method is parameterized over the AMD product line the certificate belongs to, since Milan,
+// Genoa, and Turin each have their own VCEK/ASK/ARK.
+//
+// ASK/ARK are not fetched through the Fetcher: they must already be present in the backing
+// store (pre-placed on disk, in a Secret, …), except that FilesystemCache can fetch them via its
+// Bootstrap hook. GetASK/GetARK just return them; no backend applies a TTL to them.
+type Cache interface {
+	// GetVCEK returns the VCEK certificate for the given product, chip id, and reported TCB,
+	// fetching it on a cache miss or once it has expired.
+	GetVCEK(p product.Product, chipID []byte, tcb kds.TCBVersion) ([]byte, error)
+	// GetASK returns the AMD SEV Key (ASK) certificate for p.
+	GetASK(p product.Product) ([]byte, error)
+	// GetARK returns the AMD Root Key (ARK) certificate for p.
+	GetARK(p product.Product) ([]byte, error)
+}
+
+// Fetcher retrieves VCEK certificates from AMD's Key Distribution Service.
+type Fetcher interface {
+	FetchVCEK(p product.Product, chipID []byte, tcb kds.TCBVersion) ([]byte, error)
+}
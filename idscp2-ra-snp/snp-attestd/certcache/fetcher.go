@@ -0,0 +1,75 @@
+package certcache
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/go-sev-guest/kds"
+	"github.com/google/go-sev-guest/verify/trust"
+	"golang.org/x/sync/singleflight"
+
+	log "github.com/industrial-data-space/idscp2-rat-drivers/idscp2-ra-snp/snp-attestd/logger"
+	"github.com/industrial-data-space/idscp2-rat-drivers/idscp2-ra-snp/snp-attestd/product"
+)
+
+const (
+	initialBackoff = 1 * time.Second
+	maxAttempts    = 5
+)
+
+// KDSFetcher fetches VCEK certificates straight from AMD's Key Distribution Service,
+// deduplicating concurrent requests for the same URL and retrying with exponential backoff when
+// KDS responds with HTTP 429.
+type KDSFetcher struct {
+	Getter trust.HTTPSGetter
+
+	group singleflight.Group
+}
+
+// NewKDSFetcher constructs a KDSFetcher using getter to perform HTTP requests.
+func NewKDSFetcher(getter trust.HTTPSGetter) *KDSFetcher {
+	return &KDSFetcher{Getter: getter}
+}
+
+func (f *KDSFetcher) FetchVCEK(p product.Product, chipID []byte, tcb kds.TCBVersion) ([]byte, error) {
+	url, err := kds.VCEKCertURL(p.Proto(), chipID, tcb)
+	if err != nil {
+		return nil, fmt.Errorf("could not determine the VCEK certificate's KDS URL: %w", err)
+	}
+	return f.get(url)
+}
+
+// get fetches url, coalescing concurrent callers and retrying on rate limiting.
+func (f *KDSFetcher) get(url string) ([]byte, error) {
+	v, err, _ := f.group.Do(url, func() (interface{}, error) {
+		backoff := initialBackoff
+		var lastErr error
+		for attempt := 0; attempt < maxAttempts; attempt++ {
+			data, err := f.Getter.Get(url)
+			if err == nil {
+				return data, nil
+			}
+			if !isRateLimited(err) {
+				return nil, err
+			}
+
+			lastErr = err
+			log.Debug("AMD KDS rate-limited a request to %s, retrying in %s", url, backoff)
+			time.Sleep(backoff)
+			backoff *= 2
+		}
+		return nil, fmt.Errorf("giving up after %d attempts due to AMD KDS rate limiting: %w", maxAttempts, lastErr)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.([]byte), nil
+}
+
+// isRateLimited reports whether err looks like it came from an HTTP 429 response. The
+// trust.HTTPSGetter contract only returns an error, so we're stuck sniffing its text for the
+// status code rather than inspecting a structured response.
+func isRateLimited(err error) bool {
+	return strings.Contains(err.Error(), "429")
+}
@@ -0,0 +1,136 @@
+package certcache
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/google/go-sev-guest/kds"
+	"github.com/google/go-sev-guest/verify/trust"
+
+	"github.com/industrial-data-space/idscp2-rat-drivers/idscp2-ra-snp/snp-attestd/amdkds"
+	"github.com/industrial-data-space/idscp2-rat-drivers/idscp2-ra-snp/snp-attestd/product"
+)
+
+// FilesystemCache is a TTL-aware version of snp-attestd's original on-disk VCEK cache: VCEK
+// certificates are stored at `${Dir}/${SHA-1(product|chipID|tcb)}.crt` and re-fetched once
+// older than TTL, while ASK/ARK are expected to already sit at `${Dir}/ask-<product>.crt` and
+// `${Dir}/ark-<product>.crt` unless Bootstrap is set.
+type FilesystemCache struct {
+	Dir     string
+	TTL     time.Duration
+	Fetcher Fetcher
+
+	// Bootstrap, if set, is invoked whenever the requested ASK or ARK file of a product is
+	// missing from Dir instead of returning an error. Operators wire in amdkds.Bootstrap here to fetch and
+	// verify AMD's root of trust automatically rather than requiring the files to be
+	// pre-placed.
+	Bootstrap func(p product.Product) error
+}
+
+// NewFilesystemCache constructs a FilesystemCache rooted at dir, fetching VCEK misses via
+// fetcher. A zero ttl falls back to DefaultTTL.
+func NewFilesystemCache(dir string, ttl time.Duration, fetcher Fetcher) *FilesystemCache {
+	if ttl == 0 {
+		ttl = DefaultTTL
+	}
+	return &FilesystemCache{Dir: dir, TTL: ttl, Fetcher: fetcher}
+}
+
+// NewFilesystemCacheFromConfig constructs a FilesystemCache the same way NewFilesystemCache
+// does, additionally wiring Bootstrap to amdkds.Bootstrap when autoFetchRootChain is set (i.e.
+// Config.AutoFetchRootChain), so a missing ASK/ARK is fetched and verified from AMD's KDS via
+// getter instead of requiring operators to pre-place the files.
+func NewFilesystemCacheFromConfig(dir string, ttl time.Duration, fetcher Fetcher, getter trust.HTTPSGetter, autoFetchRootChain bool) *FilesystemCache {
+	cache := NewFilesystemCache(dir, ttl, fetcher)
+	if autoFetchRootChain {
+		cache.Bootstrap = func(p product.Product) error {
+			return amdkds.Bootstrap(dir, p, getter)
+		}
+	}
+	return cache
+}
+
+func (c *FilesystemCache) GetVCEK(p product.Product, chipID []byte, tcb kds.TCBVersion) ([]byte, error) {
+	hash := sha1.New()
+	hash.Write([]byte(p.Name))
+	hash.Write(chipID)
+	fmt.Fprintf(hash, "%x", uint64(tcb))
+	path := filepath.Join(c.Dir, hex.EncodeToString(hash.Sum(nil))+".crt")
+
+	if data, ok := c.readFresh(path); ok {
+		return data, nil
+	}
+
+	data, err := c.Fetcher.FetchVCEK(p, chipID, tcb)
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch VCEK certificate: %w", err)
+	}
+
+	if err := c.write(path, data); err != nil {
+		return nil, fmt.Errorf("could not cache VCEK certificate: %w", err)
+	}
+
+	return data, nil
+}
+
+func (c *FilesystemCache) GetASK(p product.Product) ([]byte, error) {
+	return c.readRootCert(p, p.CacheFileName("ask"))
+}
+
+func (c *FilesystemCache) GetARK(p product.Product) ([]byte, error) {
+	return c.readRootCert(p, p.CacheFileName("ark"))
+}
+
+// readRootCert reads name (e.g. ask-milan.crt) from Dir, bootstrapping p's root of trust into
+// Dir first if it isn't there yet and a Bootstrap func was configured.
+func (c *FilesystemCache) readRootCert(p product.Product, name string) ([]byte, error) {
+	path := filepath.Join(c.Dir, name)
+
+	data, err := os.ReadFile(path)
+	if err == nil {
+		return data, nil
+	}
+	if !errors.Is(err, fs.ErrNotExist) || c.Bootstrap == nil {
+		return nil, err
+	}
+
+	if err := c.Bootstrap(p); err != nil {
+		return nil, fmt.Errorf("could not bootstrap AMD's root of trust: %w", err)
+	}
+
+	return os.ReadFile(path)
+}
+
+// readFresh returns the cached file's contents if it exists and is younger than the cache's
+// TTL.
+func (c *FilesystemCache) readFresh(path string) ([]byte, bool) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, false
+	}
+
+	if time.Since(info.ModTime()) > c.TTL {
+		return nil, false
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+
+	return data, true
+}
+
+func (c *FilesystemCache) write(path string, data []byte) error {
+	if err := os.MkdirAll(c.Dir, 0755); err != nil && !errors.Is(err, fs.ErrExist) {
+		return fmt.Errorf("could not create cache directory: %w", err)
+	}
+
+	return os.WriteFile(path, data, 0644)
+}
@@ -0,0 +1,133 @@
+package certcache
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/go-sev-guest/kds"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/industrial-data-space/idscp2-rat-drivers/idscp2-ra-snp/snp-attestd/product"
+)
+
+// vcekExpiresSuffix marks the ConfigMap BinaryData entry that records when the VCEK entry with
+// the same key prefix was cached, so KubernetesCache can apply a TTL on top of a plain ConfigMap
+// the way FilesystemCache does with file mtimes. A Kubernetes annotation key only allows a
+// single "/" (the prefix/name separator), so unlike an annotation this can be keyed per entry
+// without producing an invalid qualified name.
+const vcekExpiresSuffix = ".expires"
+
+// KubernetesCache stores VCEK certificates in a ConfigMap and ASK/ARK in a Secret, so a single
+// fetcher pod can populate the cache for every verifier pod in a cluster rather than each one
+// hitting AMD's KDS independently.
+type KubernetesCache struct {
+	Client    kubernetes.Interface
+	Namespace string
+	ConfigMap string
+	Secret    string
+	TTL       time.Duration
+	Fetcher   Fetcher
+}
+
+// NewKubernetesCache constructs a KubernetesCache backed by the named ConfigMap (for VCEK) and
+// Secret (for ASK/ARK) in namespace. A zero ttl falls back to DefaultTTL.
+func NewKubernetesCache(client kubernetes.Interface, namespace, configMap, secret string, ttl time.Duration, fetcher Fetcher) *KubernetesCache {
+	if ttl == 0 {
+		ttl = DefaultTTL
+	}
+	return &KubernetesCache{
+		Client:    client,
+		Namespace: namespace,
+		ConfigMap: configMap,
+		Secret:    secret,
+		TTL:       ttl,
+		Fetcher:   fetcher,
+	}
+}
+
+func (c *KubernetesCache) GetVCEK(p product.Product, chipID []byte, tcb kds.TCBVersion) ([]byte, error) {
+	ctx := context.Background()
+	key := fmt.Sprintf("%s-%s-%x.crt", p.Name, hex.EncodeToString(chipID), uint64(tcb))
+
+	cm, err := c.Client.CoreV1().ConfigMaps(c.Namespace).Get(ctx, c.ConfigMap, metav1.GetOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return nil, fmt.Errorf("could not read ConfigMap %s/%s: %w", c.Namespace, c.ConfigMap, err)
+	}
+	if cm == nil {
+		cm = &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: c.ConfigMap, Namespace: c.Namespace}}
+	}
+
+	if data, ok := c.readFresh(cm, key); ok {
+		return data, nil
+	}
+
+	data, err := c.Fetcher.FetchVCEK(p, chipID, tcb)
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch VCEK certificate: %w", err)
+	}
+
+	if err := c.store(ctx, cm, key, data); err != nil {
+		return nil, fmt.Errorf("could not cache VCEK certificate: %w", err)
+	}
+
+	return data, nil
+}
+
+func (c *KubernetesCache) readFresh(cm *corev1.ConfigMap, key string) ([]byte, bool) {
+	data, ok := cm.BinaryData[key]
+	if !ok {
+		return nil, false
+	}
+
+	cachedAt, err := time.Parse(time.RFC3339, string(cm.BinaryData[key+vcekExpiresSuffix]))
+	if err != nil || time.Since(cachedAt) > c.TTL {
+		return nil, false
+	}
+
+	return data, true
+}
+
+func (c *KubernetesCache) store(ctx context.Context, cm *corev1.ConfigMap, key string, data []byte) error {
+	if cm.BinaryData == nil {
+		cm.BinaryData = map[string][]byte{}
+	}
+
+	cm.BinaryData[key] = data
+	cm.BinaryData[key+vcekExpiresSuffix] = []byte(time.Now().Format(time.RFC3339))
+
+	if cm.ResourceVersion == "" {
+		_, err := c.Client.CoreV1().ConfigMaps(c.Namespace).Create(ctx, cm, metav1.CreateOptions{})
+		return err
+	}
+
+	_, err := c.Client.CoreV1().ConfigMaps(c.Namespace).Update(ctx, cm, metav1.UpdateOptions{})
+	return err
+}
+
+func (c *KubernetesCache) GetASK(p product.Product) ([]byte, error) {
+	return c.secretField(p.CacheFileName("ask"))
+}
+
+func (c *KubernetesCache) GetARK(p product.Product) ([]byte, error) {
+	return c.secretField(p.CacheFileName("ark"))
+}
+
+func (c *KubernetesCache) secretField(key string) ([]byte, error) {
+	secret, err := c.Client.CoreV1().Secrets(c.Namespace).Get(context.Background(), c.Secret, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("could not read Secret %s/%s: %w", c.Namespace, c.Secret, err)
+	}
+
+	data, ok := secret.Data[key]
+	if !ok {
+		return nil, errors.New("no " + key + " entry in Secret " + c.Namespace + "/" + c.Secret)
+	}
+
+	return data, nil
+}
@@ -0,0 +1,138 @@
+package certcache
+
+import (
+	"container/list"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/go-sev-guest/kds"
+
+	"github.com/industrial-data-space/idscp2-rat-drivers/idscp2-ra-snp/snp-attestd/product"
+)
+
+type memoryEntry struct {
+	key     string
+	data    []byte
+	expires time.Time
+}
+
+// MemoryCache is an in-memory, size-bounded VCEK cache with per-entry expiry, for deployments
+// that don't want (or can't persist) an on-disk cache. ASK/ARK are seeded once per product via
+// Seed and never evicted, since they rarely change and nothing refreshes them here.
+type MemoryCache struct {
+	TTL        time.Duration
+	MaxEntries int
+	Fetcher    Fetcher
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	order   *list.List
+	ask     map[string][]byte
+	ark     map[string][]byte
+}
+
+// NewMemoryCache constructs a MemoryCache holding at most maxEntries VCEK certificates, each
+// valid for ttl. A zero ttl falls back to DefaultTTL; a zero maxEntries falls back to 256.
+func NewMemoryCache(ttl time.Duration, maxEntries int, fetcher Fetcher) *MemoryCache {
+	if ttl == 0 {
+		ttl = DefaultTTL
+	}
+	if maxEntries == 0 {
+		maxEntries = 256
+	}
+
+	return &MemoryCache{
+		TTL:        ttl,
+		MaxEntries: maxEntries,
+		Fetcher:    fetcher,
+		entries:    make(map[string]*list.Element),
+		order:      list.New(),
+		ask:        make(map[string][]byte),
+		ark:        make(map[string][]byte),
+	}
+}
+
+// Seed installs the ASK/ARK certificates GetASK/GetARK return for p. It must be called before
+// serving requests for p, since MemoryCache has no way to fetch them on its own.
+func (c *MemoryCache) Seed(p product.Product, ask, ark []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.ask[p.Name] = ask
+	c.ark[p.Name] = ark
+}
+
+func (c *MemoryCache) GetASK(p product.Product) ([]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	data, ok := c.ask[p.Name]
+	if !ok {
+		return nil, fmt.Errorf("no ASK certificate has been seeded into the memory cache for %s", p.Name)
+	}
+	return data, nil
+}
+
+func (c *MemoryCache) GetARK(p product.Product) ([]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	data, ok := c.ark[p.Name]
+	if !ok {
+		return nil, fmt.Errorf("no ARK certificate has been seeded into the memory cache for %s", p.Name)
+	}
+	return data, nil
+}
+
+func (c *MemoryCache) GetVCEK(p product.Product, chipID []byte, tcb kds.TCBVersion) ([]byte, error) {
+	key := fmt.Sprintf("%s-%s-%x", p.Name, hex.EncodeToString(chipID), uint64(tcb))
+
+	if data, ok := c.lookup(key); ok {
+		return data, nil
+	}
+
+	data, err := c.Fetcher.FetchVCEK(p, chipID, tcb)
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch VCEK certificate: %w", err)
+	}
+
+	c.insert(key, data)
+	return data, nil
+}
+
+func (c *MemoryCache) lookup(key string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+
+	entry := elem.Value.(*memoryEntry)
+	if time.Now().After(entry.expires) {
+		c.order.Remove(elem)
+		delete(c.entries, key)
+		return nil, false
+	}
+
+	c.order.MoveToFront(elem)
+	return entry.data, true
+}
+
+func (c *MemoryCache) insert(key string, data []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.entries[key]; ok {
+		c.order.Remove(elem)
+	}
+
+	elem := c.order.PushFront(&memoryEntry{key: key, data: data, expires: time.Now().Add(c.TTL)})
+	c.entries[key] = elem
+
+	for c.order.Len() > c.MaxEntries {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*memoryEntry).key)
+	}
+}
@@ -0,0 +1,54 @@
+package snp_attestd
+
+import "github.com/google/go-sev-guest/verify/trust"
+
+// ProviderKind selects which ReportProvider backend NewAttestdServiceImpl wires up.
+type ProviderKind string
+
+const (
+	// ProviderKindDevice talks to the local SEV guest device at Config.SevDevice. This is the
+	// default when ProviderKind is left empty.
+	ProviderKindDevice ProviderKind = "device"
+	// ProviderKindMAA unwraps vTPM-bound SNP reports on Azure confidential VMs.
+	ProviderKindMAA ProviderKind = "maa"
+	// ProviderKindSimulator returns pre-canned reports and certificates for CI.
+	ProviderKindSimulator ProviderKind = "simulator"
+)
+
+// Config holds the runtime configuration for the snp-attestd service.
+type Config struct {
+	// Provider selects the ReportProvider backend. Defaults to ProviderKindDevice.
+	Provider ProviderKind
+	// SevDevice is the path to the SEV guest device, e.g. /dev/sev-guest. Used by
+	// ProviderKindDevice.
+	SevDevice string
+	// MAAReportPath is the sysfs path the HCL report is read from. Used by ProviderKindMAA.
+	MAAReportPath string
+	// Simulator holds the canned report and certificates served by ProviderKindSimulator.
+	Simulator SimulatorConfig
+	// VerifyOnly disables report generation so the service only handles VerifyReport calls.
+	VerifyOnly bool
+	// HTTPSGetter is the getter VerifyReport hands to go-sev-guest's verify.SnpAttestation. If
+	// nil, a default getter backed by the standard library's http.Client is used. VCEK lookups
+	// go through the certcache.Cache given to NewAttestdServiceImpl and its own Fetcher, not
+	// through this getter.
+	HTTPSGetter trust.HTTPSGetter
+	// AutoFetchRootChain fetches and verifies AMD's ASK/ARK root of trust via amdkds.Bootstrap
+	// the first time it isn't found in the cache's backing store, instead of requiring
+	// the per-product ASK/ARK files to be placed there ahead of time. Paranoid operators who want to keep the
+	// strict offline behavior can leave this unset. Only takes effect for callers that build
+	// their certcache.Cache via certcache.NewFilesystemCacheFromConfig; it has no effect on the
+	// in-memory or Kubernetes backends, which have no notion of bootstrapping. Also requires
+	// amdkds.TrustedARKFingerprints to be populated, since amdkds ships with no fingerprints
+	// baked in.
+	AutoFetchRootChain bool
+}
+
+// httpsGetter returns the configured HTTPSGetter, falling back to go-sev-guest's default
+// HTTP-backed getter if none was supplied.
+func (c Config) httpsGetter() trust.HTTPSGetter {
+	if c.HTTPSGetter != nil {
+		return c.HTTPSGetter
+	}
+	return trust.DefaultHTTPSGetter()
+}
@@ -0,0 +1,88 @@
+package snp_attestd
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+
+	"github.com/google/go-sev-guest/abi"
+	"github.com/google/go-sev-guest/client"
+
+	ar "github.com/industrial-data-space/idscp2-rat-drivers/idscp2-ra-snp/snp-attestd/attestation_report"
+)
+
+// SnpDevice wraps the local SEV-SNP guest device (/dev/sev-guest) and retrieves attestation
+// reports from the firmware via go-sev-guest's client package.
+type SnpDevice struct {
+	device client.Device
+}
+
+// OpenSnpDevice opens the SEV guest device at path for issuing SNP_GET_REPORT/SNP_GET_EXT_REPORT
+// requests. An empty path falls back to go-sev-guest's own default device lookup.
+func OpenSnpDevice(path string) (*SnpDevice, error) {
+	if path == "" {
+		device, err := client.OpenDevice()
+		if err != nil {
+			return nil, fmt.Errorf("could not open the default SEV guest device: %w", err)
+		}
+		return &SnpDevice{device: device}, nil
+	}
+
+	device := &client.LinuxDevice{}
+	if err := device.Open(path); err != nil {
+		return nil, fmt.Errorf("could not open the SEV guest device at %s: %w", path, err)
+	}
+
+	return &SnpDevice{device: device}, nil
+}
+
+// GetReport requests an attestation report binding reportData (at most 64 bytes) from the SEV
+// firmware. It returns both the legacy-decoded report and the raw wire-format bytes it was
+// decoded from, so callers can re-parse trust-sensitive fields via abi.ReportToProto instead of
+// trusting the legacy decode.
+func (d *SnpDevice) GetReport(reportData []byte) (ar.AttestationReport, []byte, error) {
+	var data [64]byte
+	copy(data[:], reportData)
+
+	reportBytes, err := client.GetRawReport(d.device, data)
+	if err != nil {
+		return ar.AttestationReport{}, nil, fmt.Errorf("could not fetch attestation report from firmware: %w", err)
+	}
+
+	var report ar.AttestationReport
+	if err := binary.Read(bytes.NewReader(reportBytes), binary.LittleEndian, &report); err != nil {
+		return ar.AttestationReport{}, nil, fmt.Errorf("could not decode attestation report: %w", err)
+	}
+
+	return report, reportBytes, nil
+}
+
+// GetExtendedReport behaves like GetReport, but additionally returns the VCEK/ASK/ARK
+// certificates that the firmware embeds in an extended report's GUID-indexed certificate table
+// via SNP_GET_EXT_REPORT. Any of the returned certificates may be nil if the host did not
+// provision it.
+func (d *SnpDevice) GetExtendedReport(reportData []byte) (report ar.AttestationReport, vcek, ask, ark []byte, err error) {
+	var data [64]byte
+	copy(data[:], reportData)
+
+	reportBytes, rawCerts, err := client.GetRawExtendedReport(d.device, data)
+	if err != nil {
+		err = fmt.Errorf("could not fetch extended attestation report from firmware: %w", err)
+		return
+	}
+
+	if err = binary.Read(bytes.NewReader(reportBytes), binary.LittleEndian, &report); err != nil {
+		err = fmt.Errorf("could not decode attestation report: %w", err)
+		return
+	}
+
+	var table abi.CertTable
+	if err = table.Unmarshal(rawCerts); err != nil {
+		err = fmt.Errorf("could not parse certificate table: %w", err)
+		return
+	}
+
+	chain := table.Proto()
+	vcek, ask, ark = chain.GetVcekCert(), chain.GetAskCert(), chain.GetArkCert()
+	return
+}
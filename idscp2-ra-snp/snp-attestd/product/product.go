@@ -0,0 +1,72 @@
+// Package product identifies which AMD SEV-SNP product line (Milan, Genoa, Turin, …) an
+// attestation report came from, so the rest of snp-attestd can pick the matching VCEK/ASK/ARK
+// instead of silently assuming a single product line.
+package product
+
+import (
+	"crypto/x509"
+	"fmt"
+	"strings"
+
+	spb "github.com/google/go-sev-guest/proto/sevsnp"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// Product identifies an AMD SEV-SNP product line and silicon stepping.
+type Product struct {
+	Name     string
+	Stepping uint8
+}
+
+var (
+	Milan = Product{Name: "Milan"}
+	Genoa = Product{Name: "Genoa"}
+	Turin = Product{Name: "Turin"}
+)
+
+// FromCPUID derives a Product from the CPUID family/model/stepping bits an attestation
+// report's platform info carries, mirroring the family/model ranges AMD has assigned to each
+// product line.
+func FromCPUID(familyID, modelID, stepping uint8) (Product, error) {
+	switch {
+	case familyID == 0x19 && modelID <= 0x0f:
+		return Product{Name: Milan.Name, Stepping: stepping}, nil
+	case familyID == 0x19 && modelID >= 0x10 && modelID <= 0x1f:
+		return Product{Name: Genoa.Name, Stepping: stepping}, nil
+	case familyID == 0x1a:
+		return Product{Name: Turin.Name, Stepping: stepping}, nil
+	default:
+		return Product{}, fmt.Errorf("unrecognized CPUID family 0x%x model 0x%x", familyID, modelID)
+	}
+}
+
+// Proto converts p to the go-sev-guest protobuf representation used by kds.VCEKCertURL and the
+// verify package.
+func (p Product) Proto() *spb.SevProduct {
+	name := spb.SevProduct_SEV_PRODUCT_UNKNOWN
+	switch p.Name {
+	case Milan.Name:
+		name = spb.SevProduct_SEV_PRODUCT_MILAN
+	case Genoa.Name:
+		name = spb.SevProduct_SEV_PRODUCT_GENOA
+	case Turin.Name:
+		name = spb.SevProduct_SEV_PRODUCT_TURIN
+	}
+
+	return &spb.SevProduct{
+		Name:            name,
+		MachineStepping: &wrapperspb.UInt32Value{Value: uint32(p.Stepping)},
+	}
+}
+
+// CacheFileName returns the per-product file name a Cache backend should use for kind ("ask" or
+// "ark"), e.g. "ask-milan.crt".
+func (p Product) CacheFileName(kind string) string {
+	return fmt.Sprintf("%s-%s.crt", kind, strings.ToLower(p.Name))
+}
+
+// MatchesCertificate reports whether cert was issued for p, going by AMD's convention of naming
+// the ASK/ARK's common name after the product line (e.g. "SEV-Milan").
+func (p Product) MatchesCertificate(cert *x509.Certificate) bool {
+	return strings.Contains(cert.Subject.CommonName, p.Name)
+}
@@ -0,0 +1,70 @@
+package product
+
+import (
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"testing"
+)
+
+func TestFromCPUID(t *testing.T) {
+	cases := []struct {
+		name     string
+		familyID uint8
+		modelID  uint8
+		want     string
+		wantErr  bool
+	}{
+		{name: "Milan low end", familyID: 0x19, modelID: 0x00, want: Milan.Name},
+		{name: "Milan/Genoa boundary, Milan side", familyID: 0x19, modelID: 0x0f, want: Milan.Name},
+		{name: "Milan/Genoa boundary, Genoa side", familyID: 0x19, modelID: 0x10, want: Genoa.Name},
+		{name: "Genoa high end", familyID: 0x19, modelID: 0x1f, want: Genoa.Name},
+		{name: "Genoa/unrecognized boundary", familyID: 0x19, modelID: 0x20, wantErr: true},
+		{name: "Turin", familyID: 0x1a, modelID: 0x00, want: Turin.Name},
+		{name: "Turin, high model", familyID: 0x1a, modelID: 0xff, want: Turin.Name},
+		{name: "unrecognized family", familyID: 0x18, modelID: 0x00, wantErr: true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			const stepping = 0x2
+			got, err := FromCPUID(tc.familyID, tc.modelID, stepping)
+
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("FromCPUID(0x%x, 0x%x, _) = %+v, want an error", tc.familyID, tc.modelID, got)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("FromCPUID(0x%x, 0x%x, _) returned an unexpected error: %v", tc.familyID, tc.modelID, err)
+			}
+			if got.Name != tc.want {
+				t.Errorf("FromCPUID(0x%x, 0x%x, _).Name = %q, want %q", tc.familyID, tc.modelID, got.Name, tc.want)
+			}
+			if got.Stepping != stepping {
+				t.Errorf("FromCPUID(0x%x, 0x%x, _).Stepping = %d, want %d", tc.familyID, tc.modelID, got.Stepping, stepping)
+			}
+		})
+	}
+}
+
+func TestCacheFileName(t *testing.T) {
+	if got, want := Milan.CacheFileName("ask"), "ask-milan.crt"; got != want {
+		t.Errorf("CacheFileName(%q) = %q, want %q", "ask", got, want)
+	}
+	if got, want := Genoa.CacheFileName("ark"), "ark-genoa.crt"; got != want {
+		t.Errorf("CacheFileName(%q) = %q, want %q", "ark", got, want)
+	}
+}
+
+func TestMatchesCertificate(t *testing.T) {
+	cert := &x509.Certificate{Subject: pkix.Name{CommonName: "SEV-Milan"}}
+
+	if !Milan.MatchesCertificate(cert) {
+		t.Errorf("Milan.MatchesCertificate(%+v) = false, want true", cert.Subject)
+	}
+	if Genoa.MatchesCertificate(cert) {
+		t.Errorf("Genoa.MatchesCertificate(%+v) = true, want false", cert.Subject)
+	}
+}
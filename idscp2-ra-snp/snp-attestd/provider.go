@@ -0,0 +1,24 @@
+package snp_attestd
+
+import (
+	ar "github.com/industrial-data-space/idscp2-rat-drivers/idscp2-ra-snp/snp-attestd/attestation_report"
+)
+
+// ReportProvider abstracts how snp-attestd obtains attestation reports, so the daemon can run
+// against the local SEV guest device, an Azure/AWS CVM's MAA-wrapped report, or a canned
+// simulator for CI, without VerifyReport or policy checking needing to know which.
+type ReportProvider interface {
+	// GetReport returns an attestation report binding reportData, along with the raw
+	// wire-format bytes the report was decoded from. Callers that need to derive trust-sensitive
+	// fields (chip id, reported TCB, CPUID product line) should parse rawReport with
+	// abi.ReportToProto rather than trusting the legacy AttestationReport's own decode, the same
+	// way VerifyReport does, so a layout drift in the legacy struct can't silently feed wrong
+	// values into a cache lookup.
+	GetReport(reportData []byte) (report ar.AttestationReport, rawReport []byte, err error)
+
+	// GetExtendedReport behaves like GetReport, but additionally returns whichever of the
+	// VCEK/ASK/ARK certificates the provider can supply alongside the report. A provider that
+	// cannot supply a given certificate returns a nil slice for it rather than an error; the
+	// caller is expected to fall back to the configured certcache.Cache for anything missing.
+	GetExtendedReport(reportData []byte) (report ar.AttestationReport, vcek, ask, ark []byte, err error)
+}
@@ -0,0 +1,98 @@
+package snp_attestd
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"os"
+
+	ar "github.com/industrial-data-space/idscp2-rat-drivers/idscp2-ra-snp/snp-attestd/attestation_report"
+)
+
+// The Host Compatibility Layer (HCL) report Azure confidential VMs expose through the vTPM is a
+// fixed-size header, followed by the raw SNP report, followed by the runtime data that was
+// hashed into the report's report_data field when the report was generated.
+const (
+	hclHeaderSize            = 32
+	hclReportSizeOffset      = 16
+	hclRuntimeDataSizeOffset = 20
+)
+
+// MAAReportProvider retrieves attestation reports from an Azure confidential VM by unwrapping
+// the SNP report embedded in the vTPM-bound HCL report instead of talking to /dev/sev-guest
+// directly, for hosts where the guest device isn't exposed.
+type MAAReportProvider struct {
+	// ReportPath is the sysfs path the HCL report is read from, e.g.
+	// /sys/kernel/config/tsm/report/hcl/outblob.
+	ReportPath string
+}
+
+// NewMAAReportProvider constructs an MAAReportProvider reading HCL reports from reportPath.
+func NewMAAReportProvider(reportPath string) *MAAReportProvider {
+	return &MAAReportProvider{ReportPath: reportPath}
+}
+
+func (p *MAAReportProvider) readHclReport() (rawReport, runtimeData []byte, err error) {
+	data, err := os.ReadFile(p.ReportPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not read the HCL report: %w", err)
+	}
+
+	if len(data) < hclHeaderSize {
+		return nil, nil, fmt.Errorf("HCL report is too short to contain a header")
+	}
+
+	reportSize := binary.LittleEndian.Uint32(data[hclReportSizeOffset : hclReportSizeOffset+4])
+	runtimeDataSize := binary.LittleEndian.Uint32(data[hclRuntimeDataSizeOffset : hclRuntimeDataSizeOffset+4])
+
+	reportStart := hclHeaderSize
+	reportEnd := reportStart + int(reportSize)
+	runtimeDataEnd := reportEnd + int(runtimeDataSize)
+	if len(data) < runtimeDataEnd {
+		return nil, nil, fmt.Errorf("HCL report is too short to contain its advertised report and runtime data")
+	}
+
+	return data[reportStart:reportEnd], data[reportEnd:runtimeDataEnd], nil
+}
+
+func (p *MAAReportProvider) GetReport(reportData []byte) (ar.AttestationReport, []byte, error) {
+	// Unlike SnpDevice/SimulatorReportProvider, we cannot bind a caller-chosen reportData into
+	// the report: Azure already bound the HCL runtime data's hash into report_data when the
+	// firmware generated it, and the HCL report is read back verbatim rather than generated
+	// fresh per call. Refuse rather than silently ignoring the caller's nonce and handing back a
+	// report with no freshness/anti-replay guarantee against it.
+	if len(reportData) != 0 {
+		return ar.AttestationReport{}, nil, fmt.Errorf("MAAReportProvider cannot bind caller-supplied report data; the HCL report's report_data is fixed to the hash of Azure's runtime data")
+	}
+
+	rawReport, runtimeData, err := p.readHclReport()
+	if err != nil {
+		return ar.AttestationReport{}, nil, err
+	}
+
+	var report ar.AttestationReport
+	if err := binary.Read(bytes.NewReader(rawReport), binary.LittleEndian, &report); err != nil {
+		return ar.AttestationReport{}, nil, fmt.Errorf("could not decode attestation report: %w", err)
+	}
+
+	// Azure binds the HCL runtime data into the SNP report by placing its SHA-256 hash in
+	// report_data, rather than letting the caller choose report_data directly.
+	hash := sha256.Sum256(runtimeData)
+	if !bytes.Equal(report.ReportData[:len(hash)], hash[:]) {
+		return ar.AttestationReport{}, nil, fmt.Errorf("HCL runtime data does not match the report's bound report data")
+	}
+
+	return report, rawReport, nil
+}
+
+func (p *MAAReportProvider) GetExtendedReport(reportData []byte) (ar.AttestationReport, []byte, []byte, []byte, error) {
+	report, _, err := p.GetReport(reportData)
+	if err != nil {
+		return ar.AttestationReport{}, nil, nil, nil, err
+	}
+
+	// Azure CVMs do not embed ASK/ARK alongside the HCL report; callers fall back to the
+	// configured certcache.Cache for those.
+	return report, nil, nil, nil, nil
+}
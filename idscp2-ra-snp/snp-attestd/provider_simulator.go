@@ -0,0 +1,46 @@
+package snp_attestd
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+
+	ar "github.com/industrial-data-space/idscp2-rat-drivers/idscp2-ra-snp/snp-attestd/attestation_report"
+)
+
+// SimulatorConfig holds the canned report and certificates SimulatorReportProvider returns.
+type SimulatorConfig struct {
+	Report   ar.AttestationReport
+	VcekCert []byte
+	AskCert  []byte
+	ArkCert  []byte
+}
+
+// SimulatorReportProvider returns a pre-canned attestation report and certificate chain instead
+// of talking to real SEV-SNP hardware, so the daemon and its clients can be exercised in CI
+// without a CVM.
+type SimulatorReportProvider struct {
+	config SimulatorConfig
+}
+
+// NewSimulatorReportProvider constructs a SimulatorReportProvider serving config's canned data.
+func NewSimulatorReportProvider(config SimulatorConfig) *SimulatorReportProvider {
+	return &SimulatorReportProvider{config: config}
+}
+
+func (p *SimulatorReportProvider) GetReport(reportData []byte) (ar.AttestationReport, []byte, error) {
+	report := p.config.Report
+	copy(report.ReportData[:], reportData)
+
+	rawReport := new(bytes.Buffer)
+	if err := binary.Write(rawReport, binary.LittleEndian, &report); err != nil {
+		return ar.AttestationReport{}, nil, fmt.Errorf("could not encode the canned attestation report: %w", err)
+	}
+
+	return report, rawReport.Bytes(), nil
+}
+
+func (p *SimulatorReportProvider) GetExtendedReport(reportData []byte) (ar.AttestationReport, []byte, []byte, []byte, error) {
+	report, _, err := p.GetReport(reportData)
+	return report, p.config.VcekCert, p.config.AskCert, p.config.ArkCert, err
+}
@@ -0,0 +1,82 @@
+package snp_attestd
+
+import (
+	"bytes"
+	"testing"
+
+	ar "github.com/industrial-data-space/idscp2-rat-drivers/idscp2-ra-snp/snp-attestd/attestation_report"
+)
+
+func TestSimulatorReportProviderGetReportBindsReportData(t *testing.T) {
+	provider := NewSimulatorReportProvider(SimulatorConfig{Report: ar.AttestationReport{}})
+
+	reportData := []byte("challenge-nonce")
+	report, rawReport, err := provider.GetReport(reportData)
+	if err != nil {
+		t.Fatalf("GetReport returned an unexpected error: %v", err)
+	}
+
+	if !bytes.Equal(report.ReportData[:len(reportData)], reportData) {
+		t.Errorf("report data was not bound into the returned report: got %x, want %x", report.ReportData[:len(reportData)], reportData)
+	}
+	if len(rawReport) == 0 {
+		t.Error("expected non-empty raw report bytes")
+	}
+}
+
+func TestSimulatorReportProviderGetExtendedReportReturnsConfiguredCerts(t *testing.T) {
+	config := SimulatorConfig{
+		VcekCert: []byte("vcek"),
+		AskCert:  []byte("ask"),
+		ArkCert:  []byte("ark"),
+	}
+	provider := NewSimulatorReportProvider(config)
+
+	_, vcek, ask, ark, err := provider.GetExtendedReport(nil)
+	if err != nil {
+		t.Fatalf("GetExtendedReport returned an unexpected error: %v", err)
+	}
+	if !bytes.Equal(vcek, config.VcekCert) || !bytes.Equal(ask, config.AskCert) || !bytes.Equal(ark, config.ArkCert) {
+		t.Errorf("GetExtendedReport did not return the configured certificates: got (%x, %x, %x)", vcek, ask, ark)
+	}
+}
+
+func TestMAAReportProviderGetReportRejectsReportData(t *testing.T) {
+	provider := NewMAAReportProvider("/nonexistent/hcl-report")
+
+	if _, _, err := provider.GetReport([]byte("challenge-nonce")); err == nil {
+		t.Fatal("expected an error for a non-empty reportData, got nil")
+	}
+}
+
+func TestNewAttestdServiceImplSelectsReportProvider(t *testing.T) {
+	cases := []struct {
+		name    string
+		kind    ProviderKind
+		wantErr bool
+	}{
+		{name: "simulator", kind: ProviderKindSimulator, wantErr: false},
+		{name: "maa", kind: ProviderKindMAA, wantErr: false},
+		{name: "unknown", kind: "bogus", wantErr: true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			service, err := NewAttestdServiceImpl(Config{Provider: tc.kind}, nil)
+
+			if tc.wantErr {
+				if err == nil {
+					t.Fatal("expected an error for an unknown provider kind, got nil")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("NewAttestdServiceImpl returned an unexpected error: %v", err)
+			}
+			if service.provider == nil {
+				t.Fatal("expected a non-nil ReportProvider")
+			}
+		})
+	}
+}
@@ -3,20 +3,22 @@ package snp_attestd
 import (
 	"bytes"
 	"context"
-	"crypto/sha1"
 	"crypto/x509"
 	"encoding/binary"
 	"encoding/hex"
 	"errors"
 	"fmt"
-	"io/fs"
-	"os"
-	"path"
-	"strings"
+
+	"github.com/google/go-sev-guest/abi"
+	"github.com/google/go-sev-guest/kds"
+	spb "github.com/google/go-sev-guest/proto/sevsnp"
+	"github.com/google/go-sev-guest/verify"
 
 	ar "github.com/industrial-data-space/idscp2-rat-drivers/idscp2-ra-snp/snp-attestd/attestation_report"
+	"github.com/industrial-data-space/idscp2-rat-drivers/idscp2-ra-snp/snp-attestd/certcache"
 	log "github.com/industrial-data-space/idscp2-rat-drivers/idscp2-ra-snp/snp-attestd/logger"
 	"github.com/industrial-data-space/idscp2-rat-drivers/idscp2-ra-snp/snp-attestd/policy"
+	"github.com/industrial-data-space/idscp2-rat-drivers/idscp2-ra-snp/snp-attestd/product"
 	pb "github.com/industrial-data-space/idscp2-rat-drivers/idscp2-ra-snp/snp-attestd/snp_attestd_service"
 )
 
@@ -25,143 +27,44 @@ var (
 )
 
 type AttestdServiceImpl struct {
-	config Config
-	dev    *SnpDevice
+	config   Config
+	provider ReportProvider
+	cache    certcache.Cache
 
 	pb.UnimplementedSnpAttestdServiceServer
 }
 
-func NewAttestdServiceImpl(config Config) (*AttestdServiceImpl, error) {
-	var dev *SnpDevice
+// NewAttestdServiceImpl constructs an AttestdServiceImpl using cache to look up VCEK/ASK/ARK
+// certificates, so operators can wire in whichever certcache backend fits their deployment.
+func NewAttestdServiceImpl(config Config, cache certcache.Cache) (*AttestdServiceImpl, error) {
+	var provider ReportProvider
 	var err error
 
 	if !config.VerifyOnly {
-		dev, err = OpenSnpDevice(config.SevDevice)
+		switch config.Provider {
+		case "", ProviderKindDevice:
+			provider, err = OpenSnpDevice(config.SevDevice)
+		case ProviderKindMAA:
+			provider = NewMAAReportProvider(config.MAAReportPath)
+		case ProviderKindSimulator:
+			provider = NewSimulatorReportProvider(config.Simulator)
+		default:
+			err = fmt.Errorf("unknown report provider %q", config.Provider)
+		}
 		if err != nil {
 			return nil, err
 		}
 	}
 
 	service := AttestdServiceImpl{
-		config: config,
-		dev:    dev,
+		config:   config,
+		provider: provider,
+		cache:    cache,
 	}
 
 	return &service, nil
 }
 
-func (s *AttestdServiceImpl) getVcekCertPath(report ar.AttestationReport) (string, error) {
-	// Each certificate is identified by the chip id and reported TCB value of the system.
-	// Both values can be found in the attestation report
-	// VCEK certificates are stored at `${config.CacheDir}/${SHA-1(report.ChipId | report.ReportedTcb)}`
-	hash := sha1.New()
-	if err := binary.Write(hash, binary.LittleEndian, &report.ChipId); err != nil {
-		return "", fmt.Errorf("could not extend hash value: %w", err)
-	}
-	if err := binary.Write(hash, binary.LittleEndian, &report.ReportedTcb); err != nil {
-		return "", fmt.Errorf("could not extend hash value: %w", err)
-	}
-
-	var pathBuilder strings.Builder
-	// The errors of strings.Builder are only here for interface compatibility and can sefely be ignored
-	pathBuilder.WriteString(s.config.CacheDir)
-	pathBuilder.WriteRune(os.PathSeparator)
-	pathBuilder.WriteString(hex.EncodeToString(hash.Sum(nil)))
-	pathBuilder.WriteString(".crt")
-
-	return pathBuilder.String(), nil
-}
-
-func (s *AttestdServiceImpl) getVcekCert(report ar.AttestationReport) ([]byte, error) {
-	filePath, err := s.getVcekCertPath(report)
-	if err != nil {
-		return []byte{}, fmt.Errorf("could not determine the VCEK certificate's location: %w", err)
-	}
-
-	_, err = os.Stat(filePath)
-	if err != nil {
-		// If the file does not exist, we can fetch it
-		// If any other error occurrs, we complain
-		if !errors.Is(err, fs.ErrNotExist) {
-			return []byte{}, fmt.Errorf("could not stat the cached certificate: %w", err)
-		}
-
-		// Create the vcek cache directory, if it does not exist
-		if err := os.MkdirAll(s.config.CacheDir, 0755); err != nil {
-			log.Debug("VCEK cache dir does not exist at %s. Creating...", s.config.CacheDir)
-			return []byte{}, fmt.Errorf("the VCEK cache dir does not exist and could not be created: %w", err)
-		}
-
-		log.Debug("Fetching VCEK certificate from AMD KDC")
-		certData, err := FetchVcekCertForReport(report)
-		if err != nil {
-			return []byte{}, fmt.Errorf("could not fetch VCEK certificate: %w", err)
-		}
-
-		// Write certificate to disk
-		// If this fails, we can continue execution
-		// Therefore we only complain to log and do not return an error
-		if err := os.WriteFile(filePath, certData, 0755); err != nil {
-			log.Warn("could not save VCEK certificate to cache: %v", err)
-		}
-
-		return certData, nil
-	}
-
-	certData, err := os.ReadFile(filePath)
-	if err != nil {
-		return []byte{}, fmt.Errorf("error reading VCEK certificate from file: %w", err)
-	}
-
-	log.Debug("Fetching VCEK from cache")
-	return certData, nil
-}
-
-func (s *AttestdServiceImpl) loadCertChain() (ask *x509.Certificate, ark *x509.Certificate, err error) {
-	askPath := path.Join(s.config.CacheDir, "ask.crt")
-	arkPath := path.Join(s.config.CacheDir, "ark.crt")
-
-	_, err = os.Stat(askPath)
-	if err != nil {
-		err = fmt.Errorf("could not stat the ASK's certificate file: %w", err)
-		return
-	}
-
-	_, err = os.Stat(arkPath)
-	if err != nil {
-		err = fmt.Errorf("could not stat the ARK's certificate file: %w", err)
-		return
-	}
-
-	loadCert := func(path string) (*x509.Certificate, error) {
-		contents, err := os.ReadFile(path)
-		if err != nil {
-			return nil, fmt.Errorf("error reading from file: %w", err)
-		}
-
-		cert, err := x509.ParseCertificate(contents)
-		if err != nil {
-			return nil, fmt.Errorf("could not decode certificate: %w", err)
-		}
-
-		return cert, nil
-	}
-
-	ask, err = loadCert(askPath)
-	if err != nil {
-		err = fmt.Errorf("could not load the ASK certificate: %w", err)
-		return
-	}
-
-	ark, err = loadCert(arkPath)
-	if err != nil {
-		err = fmt.Errorf("could not load the ARK certificate: %w", err)
-		return
-	}
-
-	return
-}
-
 // Implementation of the grpc interface
 
 func (s *AttestdServiceImpl) GetReport(ctx context.Context, reportRequest *pb.ReportRequest) (*pb.ReportResponse, error) {
@@ -178,7 +81,35 @@ func (s *AttestdServiceImpl) GetReport(ctx context.Context, reportRequest *pb.Re
 		log.Debug("Got a report request with report data %s", hex.EncodeToString(reportRequest.ReportData))
 	}
 
-	report, err := s.dev.GetReport(reportRequest.ReportData)
+	// An extended report comes bundled with whichever VCEK/ASK/ARK certificates the report
+	// provider supplies (for the SEV guest device, its extended report certificate table), so it
+	// takes a different path than a bare report plus an optional, separately-fetched VCEK.
+	if reportRequest.IncludeCertChain {
+		report, vcekCert, askCert, arkCert, err := s.provider.GetExtendedReport(reportRequest.ReportData)
+		if err != nil {
+			log.Err("Error retreiving extended report from the SEV firmware: %v", err)
+			return nil, errServer
+		}
+
+		reportBuffer := new(bytes.Buffer)
+		if err := binary.Write(reportBuffer, binary.LittleEndian, &report); err != nil {
+			log.Err("Could not encode attestation report: %v", err)
+			return nil, errServer
+		}
+
+		response := pb.ReportResponse{
+			Report: reportBuffer.Bytes(),
+			CertChain: &pb.CertificateChain{
+				VcekCert: vcekCert,
+				AskCert:  askCert,
+				ArkCert:  arkCert,
+			},
+		}
+
+		return &response, nil
+	}
+
+	report, rawReport, err := s.provider.GetReport(reportRequest.ReportData)
 	if err != nil {
 		log.Err("Error retreiving report from the SEV firmware: %v", err)
 		return nil, errServer
@@ -186,7 +117,27 @@ func (s *AttestdServiceImpl) GetReport(ctx context.Context, reportRequest *pb.Re
 
 	var vcekCert []byte
 	if reportRequest.IncludeVcekCert {
-		vcekCert, err = s.getVcekCert(report)
+		// Derive the product, chip id, and TCB used for the VCEK cache key from the
+		// go-sev-guest-parsed report rather than the legacy decode above, for the same reason
+		// VerifyReport does: a layout drift in the legacy struct must not silently feed wrong
+		// values into the cache lookup.
+		reportProto, err := abi.ReportToProto(rawReport)
+		if err != nil {
+			log.Err("Could not parse attestation report: %v", err)
+			return nil, errServer
+		}
+
+		prod, err := product.FromCPUID(
+			uint8(reportProto.GetCpuidFamId()),
+			uint8(reportProto.GetCpuidModId()),
+			uint8(reportProto.GetCpuidStep()),
+		)
+		if err != nil {
+			log.Err("Could not determine the report's product line: %v", err)
+			return nil, errServer
+		}
+
+		vcekCert, err = s.cache.GetVCEK(prod, reportProto.GetChipId(), kds.TCBVersion(reportProto.GetReportedTcb()))
 		if err != nil {
 			log.Err("Could not fetch vcek certificate: %v", err)
 			return nil, errServer
@@ -213,70 +164,90 @@ func (s *AttestdServiceImpl) VerifyReport(ctx context.Context, verifyRequest *pb
 		log.Trace("Policy: %s", verifyRequest.Policies)
 	}
 
-	var report ar.AttestationReport
-	reportBuf := bytes.NewReader(verifyRequest.Report)
-	binary.Read(reportBuf, binary.LittleEndian, &report)
-
-	ask, ark, err := s.loadCertChain()
+	// Parse the incoming report through go-sev-guest so its layout parsing stays in lock-step
+	// with the kernel's extended-report format instead of our own hand-rolled binary.Read.
+	// Everything that feeds a trust decision -- chip id, reported TCB, CPUID product detection --
+	// is derived from this parse; the legacy ar.AttestationReport below is kept only because
+	// policy.CheckPolicies still takes that type, and never overrides anything reportProto
+	// already told us.
+	reportProto, err := abi.ReportToProto(verifyRequest.Report)
 	if err != nil {
-		log.Err("Could not load the VCEK certificate chain: %v", err)
-		return nil, errServer
+		log.Err("Could not parse attestation report: %v", err)
+		return nil, fmt.Errorf("could not parse attestation report: %w", err)
 	}
 
-	// Step one: Verify that the VCEK is signed by AMD
-
-	var vcekBytes []byte
-
-	if len(verifyRequest.VcekCert) != 0 {
-		vcekBytes = verifyRequest.VcekCert
-	} else {
-		vcekBytes, err = FetchVcekCertForReport(report)
-		if err != nil {
-			log.Err("Could not fetch VCEK certificate: %v", err)
-			return nil, errServer
-		}
+	var report ar.AttestationReport
+	reportBuf := bytes.NewReader(verifyRequest.Report)
+	if err := binary.Read(reportBuf, binary.LittleEndian, &report); err != nil {
+		log.Err("Could not decode attestation report for policy checking: %v", err)
+		return nil, fmt.Errorf("could not decode attestation report: %w", err)
 	}
 
-	vcek, err := x509.ParseCertificate(vcekBytes)
+	prod, err := product.FromCPUID(
+		uint8(reportProto.GetCpuidFamId()),
+		uint8(reportProto.GetCpuidModId()),
+		uint8(reportProto.GetCpuidStep()),
+	)
 	if err != nil {
-		log.Err("Could not decode the VCEK certificate: %v", err)
-		return nil, errServer
+		log.Err("Could not determine the report's product line: %v", err)
+		return nil, fmt.Errorf("could not determine the report's product line: %w", err)
+	}
+
+	// A caller that already holds the full VCEK/ASK/ARK chain (e.g. it came bundled in an
+	// extended report from an Azure/AWS CVM) can supply it directly, sparing every verifier
+	// from needing offline ASK/ARK files or a KDS lookup. We only fall back to the cache for
+	// whichever certificate is missing from it.
+	var vcekBytes, askBytes, arkBytes []byte
+	if chain := verifyRequest.CertChain; chain != nil {
+		vcekBytes, askBytes, arkBytes = chain.VcekCert, chain.AskCert, chain.ArkCert
+	}
+
+	if len(vcekBytes) == 0 {
+		if len(verifyRequest.VcekCert) != 0 {
+			vcekBytes = verifyRequest.VcekCert
+		} else {
+			vcekBytes, err = s.cache.GetVCEK(prod, reportProto.GetChipId(), kds.TCBVersion(reportProto.GetReportedTcb()))
+			if err != nil {
+				log.Err("Could not fetch VCEK certificate: %v", err)
+				return nil, errServer
+			}
+		}
 	}
 
-	verifyOptions := x509.VerifyOptions{}
-	verifyOptions.Roots = x509.NewCertPool()
-	verifyOptions.Roots.AddCert(ark)
-	verifyOptions.Intermediates = x509.NewCertPool()
-	verifyOptions.Intermediates.AddCert(ask)
-
-	chains, err := vcek.Verify(verifyOptions)
-	if err != nil {
-		log.Err("Error during certificate verification: %v", err)
-		return nil, errServer
+	if len(askBytes) == 0 {
+		if askBytes, err = s.cache.GetASK(prod); err != nil {
+			log.Err("Could not load the ASK certificate: %v", err)
+			return nil, errServer
+		}
 	}
 
-	// For verification to be successful, there must be exactly one certificate chain
-	// vcek -> ask -> ark
-	if len(chains) != 1 || len(chains[0]) != 3 {
-		log.Debug("Report verification failed as the VCEK certificate's signature could not be verified.")
-		return &pb.VerifyResponse{}, nil
+	if len(arkBytes) == 0 {
+		if arkBytes, err = s.cache.GetARK(prod); err != nil {
+			log.Err("Could not load the ARK certificate: %v", err)
+			return nil, errServer
+		}
 	}
 
-	if !VerifyVcekCertificateExtensions(vcek, report) {
-		log.Debug("Report verification failed as the VCEK certificate's X.509 extensions did not match the report.")
+	// Reject a chain whose ASK/ARK were issued for a different product line than the report
+	// itself claims, e.g. a Milan report paired with a Genoa root of trust.
+	if err := verifyCertChainProduct(prod, askBytes, arkBytes); err != nil {
+		log.Debug("Report verification failed: %v", err)
 		return &pb.VerifyResponse{}, nil
 	}
 
-	// Step two: Verify the report signature
-
-	ok, err := report.VerifySignature(verifyRequest.Report, vcek)
-	if err != nil {
-		log.Err("Error trying to verify the report's signature: %v", err)
-		return nil, errServer
+	// Steps one and two: verify the VCEK's signature chain up to AMD's root of trust, that its
+	// X.509 extensions match the report, and that the report itself is signed by the VCEK.
+	attestation := &spb.Attestation{
+		Report: reportProto,
+		CertificateChain: &spb.CertificateChain{
+			VcekCert: vcekBytes,
+			AskCert:  askBytes,
+			ArkCert:  arkBytes,
+		},
 	}
 
-	if !ok {
-		log.Debug("Report verification failed as the report's siganture could not be verified.")
+	if err := verify.SnpAttestation(attestation, &verify.Options{Getter: s.config.httpsGetter()}); err != nil {
+		log.Debug("Report verification failed: %v", err)
 		return &pb.VerifyResponse{}, nil
 	}
 
@@ -305,4 +276,26 @@ func (s *AttestdServiceImpl) VerifyReport(ctx context.Context, verifyRequest *pb
 		Ok: true,
 	}
 	return &response, nil
-}
\ No newline at end of file
+}
+
+// verifyCertChainProduct checks that the ASK and ARK certificates were issued for prod, so a
+// report can't be verified against another product line's root of trust.
+func verifyCertChainProduct(prod product.Product, askDER, arkDER []byte) error {
+	ask, err := x509.ParseCertificate(askDER)
+	if err != nil {
+		return fmt.Errorf("could not parse ASK certificate: %w", err)
+	}
+	if !prod.MatchesCertificate(ask) {
+		return fmt.Errorf("ASK certificate %q was not issued for product %q", ask.Subject.CommonName, prod.Name)
+	}
+
+	ark, err := x509.ParseCertificate(arkDER)
+	if err != nil {
+		return fmt.Errorf("could not parse ARK certificate: %w", err)
+	}
+	if !prod.MatchesCertificate(ark) {
+		return fmt.Errorf("ARK certificate %q was not issued for product %q", ark.Subject.CommonName, prod.Name)
+	}
+
+	return nil
+}